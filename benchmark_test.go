@@ -35,16 +35,16 @@ func BenchmarkSampleInRange_Slow(b *testing.B) {
 	}
 }
 
-func prepareSampleInRangeFixture(N, specCnt int) (*Skiplist, []RangeSpec, []int) {
+func prepareSampleInRangeFixture(N, specCnt int) (*Skiplist, []RangeSpecOrdered, []int) {
 	z := New()
 	for i := 0; i < N; i++ {
 		z.Insert(Int(rand.Intn(N)))
 	}
 
-	specs := make([]RangeSpec, specCnt)
+	specs := make([]RangeSpecOrdered, specCnt)
 	limits := make([]int, specCnt)
 	for i := 0; i < specCnt; i++ {
-		spec := RangeSpec{Min: Int(rand.Intn(N))}
+		spec := RangeSpecOrdered{Min: Int(rand.Intn(N))}
 		//spec.Max = Int(int(spec.Min.(Int)) + rand.Intn(N-int(spec.Min.(Int))))
 		spec.Max = Int(rand.Intn(1000) + int(spec.Min.(Int)))
 		spec.Minex = (rand.Intn(2) == 0)
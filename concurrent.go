@@ -0,0 +1,423 @@
+package skiplist
+
+import (
+  "cmp"
+  "sort"
+  "sync"
+  "sync/atomic"
+)
+
+// csNode is an element of a ConcurrentSkipList. It follows the optimistic
+// skip list design from Herlihy & Shavit's "The Art of Multiprocessor
+// Programming": readers traverse next purely with atomic loads, while
+// inserters/removers lock the predecessors they touch and re-validate
+// before splicing.
+type csNode[K any, V any] struct {
+  key   K
+  value V
+  seq   int64 // assigned once at creation; used to lock nodes in a fixed global order and so avoid deadlocks.
+
+  next []atomic.Pointer[csNode[K, V]]
+  span []atomic.Int64
+
+  mu          sync.Mutex
+  marked      atomic.Bool // true once the node is logically deleted.
+  fullyLinked atomic.Bool // true once the node is linked in at every one of its levels.
+}
+
+var csNodeSeq atomic.Int64
+
+func newCsNode[K any, V any](k K, v V, levels int) *csNode[K, V] {
+  n := &csNode[K, V]{key: k, value: v, seq: csNodeSeq.Add(1)}
+  n.next = make([]atomic.Pointer[csNode[K, V]], levels)
+  n.span = make([]atomic.Int64, levels)
+  return n
+}
+
+// ConcurrentSkipList is a SkipList that supports concurrent Insert/Remove/
+// Range/SampleInRange from multiple goroutines. Searches (Get, Range,
+// SampleInRange) never block: they walk the next pointers with atomic
+// loads. Insert and Remove are optimistic: they search lock-free, then lock
+// only the predecessors they need to splice, validate those predecessors
+// are still correct, and retry if another goroutine raced them.
+//
+// The span counters used for rank-based queries (Range, SampleInRange) are
+// updated atomically alongside the pointer splice, under the same
+// predecessor locks, so a Range/SampleInRange call always sees a
+// consistent, if possibly concurrently-changing, set of ranks.
+type ConcurrentSkipList[K any, V any] struct {
+  head   *csNode[K, V]
+  cmp    CompareFn[K]
+  level  atomic.Int32
+  length atomic.Int64
+}
+
+// NewConcurrentSkipList creates a ConcurrentSkipList whose keys are
+// compared with the natural ordering of K.
+func NewConcurrentSkipList[K cmp.Ordered, V any]() *ConcurrentSkipList[K, V] {
+  return NewConcurrentSkipListFunc[K, V](func(a, b K) bool { return a < b })
+}
+
+// NewConcurrentSkipListFunc creates a ConcurrentSkipList whose keys are
+// compared with cmp.
+func NewConcurrentSkipListFunc[K any, V any](cmp CompareFn[K]) *ConcurrentSkipList[K, V] {
+  head := &csNode[K, V]{seq: 0}
+  head.next = make([]atomic.Pointer[csNode[K, V]], MaxLevel)
+  head.span = make([]atomic.Int64, MaxLevel)
+  head.fullyLinked.Store(true)
+  z := &ConcurrentSkipList[K, V]{head: head, cmp: cmp}
+  z.level.Store(1)
+  return z
+}
+
+// Cardinality returns the number of elements in the ConcurrentSkipList. It
+// is a snapshot: concurrent Insert/Remove calls may change it immediately
+// after it is read.
+func (z *ConcurrentSkipList[K, V]) Cardinality() int {
+  return int(z.length.Load())
+}
+
+// find walks down from the top level to level 0 with atomic loads only,
+// returning the predecessor and successor of k at every level, plus the
+// rank of the predecessor at every level (the same rank[] accumulation
+// Insert/Remove needs to maintain span). searchLevel is the list height find
+// observed; preds/succs/ranks only hold valid entries for i < searchLevel,
+// so callers must use this value, not a level re-read afterwards, to size
+// the levels they can safely touch.
+func (z *ConcurrentSkipList[K, V]) find(k K) (preds, succs []*csNode[K, V], ranks []int64, searchLevel int) {
+  searchLevel = int(z.level.Load())
+  preds = make([]*csNode[K, V], MaxLevel)
+  succs = make([]*csNode[K, V], MaxLevel)
+  ranks = make([]int64, MaxLevel)
+
+  pred := z.head
+  var rank int64
+  for i := searchLevel - 1; i >= 0; i-- {
+    curr := pred.next[i].Load()
+    for curr != nil && z.cmp(curr.key, k) {
+      rank += pred.span[i].Load()
+      pred = curr
+      curr = pred.next[i].Load()
+    }
+    preds[i] = pred
+    succs[i] = curr
+    ranks[i] = rank
+  }
+  return preds, succs, ranks, searchLevel
+}
+
+// Get returns the value associated with k, and whether k was found. It
+// never blocks.
+func (z *ConcurrentSkipList[K, V]) Get(k K) (V, bool) {
+  pred := z.head
+  topLevel := int(z.level.Load())
+  for i := topLevel - 1; i >= 0; i-- {
+    curr := pred.next[i].Load()
+    for curr != nil && z.cmp(curr.key, k) {
+      pred = curr
+      curr = pred.next[i].Load()
+    }
+  }
+  curr := pred.next[0].Load()
+  if curr != nil && !z.cmp(k, curr.key) && !z.cmp(curr.key, k) && curr.fullyLinked.Load() && !curr.marked.Load() {
+    return curr.value, true
+  }
+  var zero V
+  return zero, false
+}
+
+// lockUnique locks every distinct node in nodes, in a fixed global order
+// (by creation sequence number), and returns them so they can be unlocked
+// with unlockAll. Locking in a fixed order, rather than in level order,
+// avoids deadlocking against a concurrent call that touches an overlapping
+// set of predecessors.
+func (z *ConcurrentSkipList[K, V]) lockUnique(nodes []*csNode[K, V]) []*csNode[K, V] {
+  seen := make(map[*csNode[K, V]]bool, len(nodes))
+  unique := make([]*csNode[K, V], 0, len(nodes))
+  for _, n := range nodes {
+    if !seen[n] {
+      seen[n] = true
+      unique = append(unique, n)
+    }
+  }
+  sort.Slice(unique, func(i, j int) bool { return unique[i].seq < unique[j].seq })
+  for _, n := range unique {
+    n.mu.Lock()
+  }
+  return unique
+}
+
+func (z *ConcurrentSkipList[K, V]) unlockAll(nodes []*csNode[K, V]) {
+  for _, n := range nodes {
+    n.mu.Unlock()
+  }
+}
+
+// Insert adds the key k with value v to the ConcurrentSkipList. Like
+// SkipList.Insert, it does not reject duplicate keys.
+func (z *ConcurrentSkipList[K, V]) Insert(k K, v V) {
+  for {
+    // find walks every level that exists at this instant, so preds/succs
+    // already hold a fresh predecessor/successor pair for levels above
+    // the new node's own height, not just the ones it will be spliced
+    // into.
+    preds, succs, ranks, searchLevel := z.find(k)
+    nodeLevel := randLevel()
+
+    grown := false
+    for {
+      cur := int(z.level.Load())
+      if cur > searchLevel {
+        // The list grew past searchLevel since find() ran, so
+        // preds/succs have no entry for the levels in between:
+        // restart with a fresh find rather than guess at them.
+        grown = true
+        break
+      }
+      if nodeLevel <= cur {
+        break
+      }
+      if z.level.CompareAndSwap(int32(cur), int32(nodeLevel)) {
+        for i := cur; i < nodeLevel; i++ {
+          preds[i] = z.head
+          succs[i] = nil
+          ranks[i] = 0
+        }
+        break
+      }
+    }
+    if grown {
+      continue
+    }
+
+    // scope covers every level this insert touches: the ones it splices
+    // into (below nodeLevel), plus the existing higher ones whose span
+    // merely grows by one. Locking and validating all of them, not just
+    // the spliced levels, is what keeps the span update below safe from
+    // a concurrent insert/remove that changes one of those predecessors.
+    scope := nodeLevel
+    if searchLevel > scope {
+      scope = searchLevel
+    }
+
+    locked := z.lockUnique(preds[:scope])
+    valid := true
+    for i := 0; i < scope && valid; i++ {
+      valid = !preds[i].marked.Load() && (succs[i] == nil || !succs[i].marked.Load()) && preds[i].next[i].Load() == succs[i]
+    }
+    if !valid {
+      z.unlockAll(locked)
+      continue
+    }
+
+    newNode := newCsNode[K, V](k, v, nodeLevel)
+    for i := 0; i < nodeLevel; i++ {
+      newNode.next[i].Store(succs[i])
+      newNode.span[i].Store(preds[i].span[i].Load() - (ranks[0] - ranks[i]))
+      preds[i].span[i].Store(ranks[0] - ranks[i] + 1)
+      preds[i].next[i].Store(newNode)
+    }
+    // Levels above nodeLevel are untouched structurally, but their span
+    // still needs to grow by one now that an element was inserted below
+    // them. This is now safe because preds[i] was locked and validated
+    // above for every i < scope, so it is still the correct predecessor.
+    for i := nodeLevel; i < scope; i++ {
+      preds[i].span[i].Add(1)
+    }
+
+    newNode.fullyLinked.Store(true)
+    z.unlockAll(locked)
+    z.length.Add(1)
+    return
+  }
+}
+
+// Remove removes the first element keyed by k from the ConcurrentSkipList.
+// If the removal is successful, Remove returns true, otherwise, false.
+func (z *ConcurrentSkipList[K, V]) Remove(k K) bool {
+  var victim *csNode[K, V]
+  var topLevel int
+  marked := false
+
+  for {
+    // Like in Insert, find() walks every level that currently exists, so
+    // preds/succs hold a fresh pair for the levels above victim's own
+    // height too, not just the ones it is linked at.
+    preds, succs, _, searchLevel := z.find(k)
+
+    if !marked {
+      curr := succs[0]
+      if curr == nil || z.cmp(k, curr.key) || z.cmp(curr.key, k) {
+        return false
+      }
+      if !curr.fullyLinked.Load() {
+        continue
+      }
+      curr.mu.Lock()
+      if curr.marked.Load() {
+        curr.mu.Unlock()
+        continue
+      }
+      victim = curr
+      topLevel = len(victim.next)
+      victim.marked.Store(true)
+      marked = true
+      victim.mu.Unlock()
+    }
+
+    // scope covers every level this remove touches: victim's own levels,
+    // plus the existing higher ones whose span shrinks by one. Locking
+    // and validating all of them keeps the span update below safe from a
+    // concurrent insert/remove that changes one of those predecessors.
+    scope := topLevel
+    if searchLevel > scope {
+      scope = searchLevel
+    }
+
+    locked := z.lockUnique(preds[:scope])
+    valid := true
+    for i := 0; i < scope && valid; i++ {
+      if i < topLevel {
+        valid = !preds[i].marked.Load() && preds[i].next[i].Load() == victim
+      } else {
+        valid = !preds[i].marked.Load() && preds[i].next[i].Load() == succs[i]
+      }
+    }
+    if !valid {
+      z.unlockAll(locked)
+      continue
+    }
+
+    for i := topLevel - 1; i >= 0; i-- {
+      preds[i].span[i].Add(victim.span[i].Load() - 1)
+      preds[i].next[i].Store(victim.next[i].Load())
+    }
+    // Levels above topLevel are untouched structurally, but their span
+    // still needs to shrink by one now that an element was removed below
+    // them. This is safe because preds[i] was locked and validated above
+    // for every i < scope, so it is still the correct predecessor.
+    for i := topLevel; i < scope; i++ {
+      preds[i].span[i].Add(-1)
+    }
+
+    z.unlockAll(locked)
+    z.length.Add(-1)
+    return true
+  }
+}
+
+// skipMarked walks forward past nodes that Remove has logically deleted
+// (marked but not yet physically unlinked), so a reader never hands back a
+// node that is on its way out.
+func skipMarked[K any, V any](x *csNode[K, V]) *csNode[K, V] {
+  for x != nil && x.marked.Load() {
+    x = x.next[0].Load()
+  }
+  return x
+}
+
+// getElementByRank returns the node at the given 0-based rank, lock-free. If
+// the rank currently lands on a marked node, it returns the next live node
+// instead, since a marked node's span can still include it until Remove
+// finishes splicing it out.
+func (z *ConcurrentSkipList[K, V]) getElementByRank(rank int) *csNode[K, V] {
+  traversed := int64(0)
+  x := z.head
+  topLevel := int(z.level.Load())
+  for i := topLevel - 1; i >= 0; i-- {
+    for {
+      next := x.next[i].Load()
+      if next == nil || traversed+x.span[i].Load() > int64(rank+1) {
+        break
+      }
+      traversed += x.span[i].Load()
+      x = next
+    }
+    if traversed == int64(rank+1) {
+      return skipMarked(x)
+    }
+  }
+  return nil
+}
+
+// Range returns entries whose rank is between start and stop. Both
+// arguments, start and stop, are inclusive and 0-based.
+func (z *ConcurrentSkipList[K, V]) Range(start, stop int) (reply []Pair[K, V]) {
+  length := int(z.length.Load())
+  if start > stop || start >= length {
+    return nil
+  }
+  if stop >= length {
+    stop = length - 1
+  }
+
+  node := z.getElementByRank(start)
+  for rangelen := stop - start + 1; rangelen > 0 && node != nil; rangelen-- {
+    reply = append(reply, Pair[K, V]{Key: node.key, Value: node.value})
+    node = skipMarked(node.next[0].Load())
+  }
+  return reply
+}
+
+func (z *ConcurrentSkipList[K, V]) rankOfFirstInRange(spec RangeSpec[K]) int {
+  x := z.head
+  topLevel := int(z.level.Load())
+  var rank int64
+  for i := topLevel - 1; i >= 0; i-- {
+    for {
+      next := x.next[i].Load()
+      if next == nil || spec.gteMin(z.cmp, next.key) {
+        break
+      }
+      rank += x.span[i].Load()
+      x = next
+    }
+  }
+  next := x.next[0].Load()
+  if next == nil || !spec.lteMax(z.cmp, next.key) {
+    return -1
+  }
+  return int(rank)
+}
+
+func (z *ConcurrentSkipList[K, V]) rankOfLastInRange(spec RangeSpec[K]) int {
+  x := z.head
+  topLevel := int(z.level.Load())
+  rank := int64(-1)
+  for i := topLevel - 1; i >= 0; i-- {
+    for {
+      next := x.next[i].Load()
+      if next == nil || !spec.lteMax(z.cmp, next.key) {
+        break
+      }
+      rank += x.span[i].Load()
+      x = next
+    }
+  }
+  if x == z.head || !spec.gteMin(z.cmp, x.key) {
+    return -1
+  }
+  return int(rank)
+}
+
+// SampleInRange returns limit entries within spec, randomly and evenly
+// sampled by rank. Unlike SkipList.SampleInRange, it always walks from the
+// head for every sampled rank rather than reusing a shared level-walk path,
+// since that path is itself part of the structure concurrent mutations can
+// invalidate mid-walk.
+func (z *ConcurrentSkipList[K, V]) SampleInRange(spec RangeSpec[K], limit int) (reply []Pair[K, V]) {
+  first := z.rankOfFirstInRange(spec)
+  last := z.rankOfLastInRange(spec)
+  if first == -1 || last == -1 {
+    return nil
+  }
+
+  ranks := Sample(limit, last-first+1)
+  for _, r := range ranks {
+    node := z.getElementByRank(r + first)
+    if node != nil {
+      reply = append(reply, Pair[K, V]{Key: node.key, Value: node.value})
+    }
+  }
+  return reply
+}
@@ -0,0 +1,87 @@
+package skiplist
+
+import (
+  "math/rand"
+  "sync"
+  "testing"
+)
+
+// rwMutexSkipList is the naive baseline: a SkipList guarded by a single
+// sync.RWMutex. BenchmarkRWMutexSkipList_* exists to show how much
+// ConcurrentSkipList's per-node locking buys over this as GOMAXPROCS grows.
+type rwMutexSkipList[K any, V any] struct {
+  mu   sync.RWMutex
+  core *SkipList[K, V]
+}
+
+func newRWMutexSkipList[K any, V any](cmp CompareFn[K]) *rwMutexSkipList[K, V] {
+  return &rwMutexSkipList[K, V]{core: NewSkipListFunc[K, V](cmp)}
+}
+
+func (z *rwMutexSkipList[K, V]) Insert(k K, v V) {
+  z.mu.Lock()
+  defer z.mu.Unlock()
+  z.core.Insert(k, v)
+}
+
+func (z *rwMutexSkipList[K, V]) Get(k K) (V, bool) {
+  z.mu.RLock()
+  defer z.mu.RUnlock()
+  return z.core.Get(k)
+}
+
+func BenchmarkConcurrentSkipList_Get(b *testing.B) {
+  N := 100000
+  z := NewConcurrentSkipListFunc[int, int](intLess)
+  for i := 0; i < N; i++ {
+    z.Insert(i, i)
+  }
+
+  b.ResetTimer()
+  b.RunParallel(func(pb *testing.PB) {
+    rnd := rand.New(rand.NewSource(1))
+    for pb.Next() {
+      z.Get(rnd.Intn(N))
+    }
+  })
+}
+
+func BenchmarkRWMutexSkipList_Get(b *testing.B) {
+  N := 100000
+  z := newRWMutexSkipList[int, int](intLess)
+  for i := 0; i < N; i++ {
+    z.Insert(i, i)
+  }
+
+  b.ResetTimer()
+  b.RunParallel(func(pb *testing.PB) {
+    rnd := rand.New(rand.NewSource(1))
+    for pb.Next() {
+      z.Get(rnd.Intn(N))
+    }
+  })
+}
+
+func BenchmarkConcurrentSkipList_Insert(b *testing.B) {
+  z := NewConcurrentSkipListFunc[int, int](intLess)
+
+  b.ResetTimer()
+  b.RunParallel(func(pb *testing.PB) {
+    rnd := rand.New(rand.NewSource(rand.Int63()))
+    for pb.Next() {
+      z.Insert(rnd.Int(), 0)
+    }
+  })
+}
+
+func BenchmarkRWMutexSkipList_Insert(b *testing.B) {
+  z := newRWMutexSkipList[int, int](intLess)
+
+  b.ResetTimer()
+  b.RunParallel(func(pb *testing.PB) {
+    rnd := rand.New(rand.NewSource(rand.Int63()))
+    for pb.Next() {
+      z.Insert(rnd.Int(), 0)
+    }
+  })
+}
@@ -0,0 +1,121 @@
+package skiplist
+
+import (
+  "sort"
+  "sync"
+  "testing"
+)
+
+func intLess(a, b int) bool { return a < b }
+
+func TestConcurrentSkipListSequential(t *testing.T) {
+  N := 1000
+  z := NewConcurrentSkipListFunc[int, int](intLess)
+  for i := 0; i < N; i++ {
+    z.Insert(i, i*10)
+  }
+  if z.Cardinality() != N {
+    t.Fatalf("expected cardinality %d, got %d", N, z.Cardinality())
+  }
+
+  for i := 0; i < N; i += 3 {
+    if !z.Remove(i) {
+      t.Fatalf("expected to remove %d", i)
+    }
+  }
+  if z.Cardinality() != N-len(rangeStep(N, 3)) {
+    t.Fatalf("unexpected cardinality after removal: %d", z.Cardinality())
+  }
+
+  for i := 0; i < N; i++ {
+    v, ok := z.Get(i)
+    if i%3 == 0 {
+      if ok {
+        t.Fatalf("expected %d to have been removed", i)
+      }
+      continue
+    }
+    if !ok || v != i*10 {
+      t.Fatalf("expected Get(%d) = %d, got %d (ok=%v)", i, i*10, v, ok)
+    }
+  }
+}
+
+func rangeStep(n, step int) []int {
+  var out []int
+  for i := 0; i < n; i += step {
+    out = append(out, i)
+  }
+  return out
+}
+
+func TestConcurrentSkipListConcurrentInsertRemove(t *testing.T) {
+  const goroutines = 8
+  const perGoroutine = 500
+
+  z := NewConcurrentSkipListFunc[int, int](intLess)
+  var wg sync.WaitGroup
+  for g := 0; g < goroutines; g++ {
+    wg.Add(1)
+    go func(g int) {
+      defer wg.Done()
+      for i := 0; i < perGoroutine; i++ {
+        z.Insert(g*perGoroutine+i, i)
+      }
+    }(g)
+  }
+  wg.Wait()
+
+  if got, want := z.Cardinality(), goroutines*perGoroutine; got != want {
+    t.Fatalf("expected cardinality %d, got %d", want, got)
+  }
+
+  // Range must return every key in ascending order, with spans kept
+  // consistent by the concurrent inserts above.
+  got := z.Range(0, z.Cardinality()-1)
+  keys := make([]int, len(got))
+  for i, p := range got {
+    keys[i] = p.Key
+  }
+  if !sort.IntsAreSorted(keys) {
+    t.Fatalf("Range result is not sorted: %v", keys)
+  }
+  if len(keys) != goroutines*perGoroutine {
+    t.Fatalf("expected %d keys, got %d", goroutines*perGoroutine, len(keys))
+  }
+
+  var wg2 sync.WaitGroup
+  for g := 0; g < goroutines; g++ {
+    wg2.Add(1)
+    go func(g int) {
+      defer wg2.Done()
+      for i := 0; i < perGoroutine; i++ {
+        z.Remove(g*perGoroutine + i)
+      }
+    }(g)
+  }
+  wg2.Wait()
+
+  if z.Cardinality() != 0 {
+    t.Fatalf("expected empty list, got cardinality %d", z.Cardinality())
+  }
+}
+
+func TestConcurrentSkipListSampleInRange(t *testing.T) {
+  N := 200
+  z := NewConcurrentSkipListFunc[int, int](intLess)
+  for i := 0; i < N; i++ {
+    z.Insert(i, i)
+  }
+
+  spec := RangeSpec[int]{Min: 10, Max: 100}
+  sampled := z.SampleInRange(spec, 20)
+  if len(sampled) != 20 {
+    t.Fatalf("expected 20 samples, got %d", len(sampled))
+  }
+  for _, p := range sampled {
+    if p.Key < 10 || p.Key > 100 {
+      t.Fatalf("sample %d out of range [10,100]", p.Key)
+    }
+  }
+}
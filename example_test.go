@@ -30,7 +30,7 @@ func ExampleSkiplist_SampleInRange() {
 	for i := 0; i < 20; i++ {
 		z.Insert(Int(i))
 	}
-	sampled := z.SampleInRange(RangeSpec{Min: Int(4), Max: Int(17)}, 5)
+	sampled := z.SampleInRange(RangeSpecOrdered{Min: Int(4), Max: Int(17)}, 5)
 	fmt.Println(sampled)
 
 	// Output:
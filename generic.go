@@ -0,0 +1,636 @@
+package skiplist
+
+import (
+  "cmp"
+  "container/heap"
+  "fmt"
+  "math"
+  "math/rand"
+  "sort"
+)
+
+// CompareFn reports whether a is strictly less than b. SkipList uses it
+// instead of the Ordered interface so that comparisons are direct function
+// calls on typed keys rather than interface{} boxing plus a type assertion,
+// which is what dominates the inner loops of Skiplist's Add/Rem/SampleInRange.
+type CompareFn[K any] func(a, b K) bool
+
+// RangeSpec is a generic interval of keys with information about the
+// inclusiveness of its boundaries. RangeSpecOrdered is its Ordered-based
+// counterpart, kept for backward compatibility.
+type RangeSpec[K any] struct {
+  Min, Max     K
+  Minex, Maxex bool // End points are excluded if Minex or Maxex is true.
+}
+
+func (spec *RangeSpec[K]) gteMin(cmp CompareFn[K], k K) bool {
+  if spec.Minex {
+    return cmp(spec.Min, k)
+  }
+  return !cmp(k, spec.Min)
+}
+
+func (spec *RangeSpec[K]) lteMax(cmp CompareFn[K], k K) bool {
+  if spec.Maxex {
+    return cmp(k, spec.Max)
+  }
+  return !cmp(spec.Max, k)
+}
+
+// Pair is a key/value entry returned by SkipList's range and sampling
+// operations.
+type Pair[K any, V any] struct {
+  Key   K
+  Value V
+}
+
+type slNode[K any, V any] struct {
+  key      K
+  value    V
+  backward *slNode[K, V]
+  level    []slLevel[K, V]
+}
+
+type slLevel[K any, V any] struct {
+  forward *slNode[K, V]
+  span    int
+}
+
+// SkipList is a generics-based skip list keyed by K holding values V. It is
+// the typed replacement for Skiplist: keys are compared through a CompareFn
+// instead of the Ordered interface, avoiding the allocation and type
+// assertion that Ordered.Less needs on every comparison.
+type SkipList[K any, V any] struct {
+  head, tail *slNode[K, V]
+  length     int
+  level      int
+  cmp        CompareFn[K]
+}
+
+// NewSkipList creates a SkipList whose keys are compared with the natural
+// ordering of K.
+func NewSkipList[K cmp.Ordered, V any]() *SkipList[K, V] {
+  return NewSkipListFunc[K, V](func(a, b K) bool { return a < b })
+}
+
+// NewSkipListFunc creates a SkipList whose keys are compared with cmp,
+// allowing K to be any type, not just one with a natural ordering.
+func NewSkipListFunc[K any, V any](cmp CompareFn[K]) *SkipList[K, V] {
+  head := &slNode[K, V]{level: make([]slLevel[K, V], MaxLevel)}
+  return &SkipList[K, V]{head: head, level: 1, cmp: cmp}
+}
+
+// Cardinality returns the number of elements in the SkipList.
+func (z *SkipList[K, V]) Cardinality() int {
+  return z.length
+}
+
+// Insert adds the key k with value v to the SkipList.
+func (z *SkipList[K, V]) Insert(k K, v V) {
+  z.insertNode(k, v)
+}
+
+// InsertNode behaves like Insert but also returns a stable handle to the
+// inserted element. RemoveNode and RankOf can later use that handle to
+// remove or rank exactly that element, even among elements with equal keys,
+// which is what lets a caller (e.g. a leaderboard) keep its own id -> element
+// index.
+func (z *SkipList[K, V]) InsertNode(k K, v V) *Node[K, V] {
+  return &Node[K, V]{n: z.insertNode(k, v)}
+}
+
+func (z *SkipList[K, V]) insertNode(k K, v V) *slNode[K, V] {
+  update := make([]*slNode[K, V], MaxLevel)
+  rank := make([]int, MaxLevel)
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    if i == z.level-1 {
+      rank[i] = 0
+    } else {
+      rank[i] = rank[i+1]
+    }
+
+    for x.level[i].forward != nil && z.cmp(x.level[i].forward.key, k) {
+      rank[i] += x.level[i].span
+      x = x.level[i].forward
+    }
+    update[i] = x
+  }
+
+  level := randLevel()
+  if level > z.level {
+    for i := z.level; i < level; i++ {
+      rank[i] = 0
+      update[i] = z.head
+      update[i].level[i].span = z.length
+    }
+    z.level = level
+  }
+  x = &slNode[K, V]{key: k, value: v, level: make([]slLevel[K, V], level)}
+  for i := 0; i < level; i++ {
+    x.level[i].forward = update[i].level[i].forward
+    update[i].level[i].forward = x
+
+    // update span covered by update[i] as x is inserted here
+    x.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+    update[i].level[i].span = (rank[0] - rank[i]) + 1
+  }
+
+  // increment span for untouched levels
+  for i := level; i < z.level; i++ {
+    update[i].level[i].span++
+  }
+
+  if update[0] == z.head {
+    x.backward = nil
+  } else {
+    x.backward = update[0]
+  }
+  if x.level[0].forward != nil {
+    x.level[0].forward.backward = x
+  } else {
+    z.tail = x
+  }
+  z.length++
+  return x
+}
+
+// Get returns the value associated with k, and whether k was found.
+func (z *SkipList[K, V]) Get(k K) (V, bool) {
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    for x.level[i].forward != nil && z.cmp(x.level[i].forward.key, k) {
+      x = x.level[i].forward
+    }
+  }
+  x = x.level[0].forward
+  if x != nil && !z.cmp(k, x.key) && !z.cmp(x.key, k) {
+    return x.value, true
+  }
+  var zero V
+  return zero, false
+}
+
+// Remove removes the element keyed by k from the SkipList.
+// If the removal is successful, Remove returns true, otherwise, false.
+func (z *SkipList[K, V]) Remove(k K) bool {
+  update := make([]*slNode[K, V], MaxLevel)
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    for x.level[i].forward != nil && z.cmp(x.level[i].forward.key, k) {
+      x = x.level[i].forward
+    }
+    update[i] = x
+  }
+
+  x = x.level[0].forward
+  if x == nil || z.cmp(k, x.key) || z.cmp(x.key, k) {
+    return false
+  }
+  for i := 0; i < z.level; i++ {
+    if update[i].level[i].forward == x {
+      update[i].level[i].span += x.level[i].span - 1
+      update[i].level[i].forward = x.level[i].forward
+    } else {
+      update[i].level[i].span -= 1
+    }
+  }
+  if x.level[0].forward != nil {
+    x.level[0].forward.backward = x.backward
+  } else {
+    z.tail = x.backward
+  }
+  for z.level > 1 && z.head.level[z.level-1].forward == nil {
+    z.level--
+  }
+  z.length--
+  return true
+}
+
+// Node is an opaque, stable handle to an element inserted via InsertNode. It
+// keeps pointing at the same element across Insert/Remove calls on other
+// keys, which is what lets RemoveNode and RankOf find exactly that element
+// even when the SkipList holds several elements with equal keys. Both cost
+// O(log N) plus the number of equal-keyed elements that precede it.
+type Node[K any, V any] struct {
+  n *slNode[K, V]
+}
+
+// Key returns the handle's key.
+func (h *Node[K, V]) Key() K { return h.n.key }
+
+// Value returns the handle's value.
+func (h *Node[K, V]) Value() V { return h.n.value }
+
+// Next returns the element that immediately follows h in ascending key
+// order, or nil if h is the last element.
+func (h *Node[K, V]) Next() *Node[K, V] {
+  if h.n.level[0].forward == nil {
+    return nil
+  }
+  return &Node[K, V]{n: h.n.level[0].forward}
+}
+
+// Prev returns the element that immediately precedes h in ascending key
+// order, or nil if h is the first element.
+func (h *Node[K, V]) Prev() *Node[K, V] {
+  if h.n.backward == nil {
+    return nil
+  }
+  return &Node[K, V]{n: h.n.backward}
+}
+
+// Head returns the first element in ascending key order, or nil if the
+// SkipList is empty.
+func (z *SkipList[K, V]) Head() *Node[K, V] {
+  x := z.head.level[0].forward
+  if x == nil {
+    return nil
+  }
+  return &Node[K, V]{n: x}
+}
+
+// Tail returns the last element in ascending key order, or nil if the
+// SkipList is empty.
+func (z *SkipList[K, V]) Tail() *Node[K, V] {
+  if z.tail == nil {
+    return nil
+  }
+  return &Node[K, V]{n: z.tail}
+}
+
+// RemoveNode removes the element referenced by handle, even if other
+// elements in the SkipList compare equal to it. It costs O(log N) plus the
+// number of equal-keyed elements that precede it.
+func (z *SkipList[K, V]) RemoveNode(handle *Node[K, V]) bool {
+  target := handle.n
+  update, _ := z.locate(target)
+  if update[0].level[0].forward != target {
+    return false
+  }
+  for i := 0; i < z.level; i++ {
+    if update[i].level[i].forward == target {
+      update[i].level[i].span += target.level[i].span - 1
+      update[i].level[i].forward = target.level[i].forward
+    } else {
+      update[i].level[i].span -= 1
+    }
+  }
+  if target.level[0].forward != nil {
+    target.level[0].forward.backward = target.backward
+  } else {
+    z.tail = target.backward
+  }
+  for z.level > 1 && z.head.level[z.level-1].forward == nil {
+    z.level--
+  }
+  z.length--
+  return true
+}
+
+// RankOf returns the 0-based rank of the element referenced by handle, and
+// whether it is still in the SkipList.
+func (z *SkipList[K, V]) RankOf(handle *Node[K, V]) (int, bool) {
+  update, rank := z.locate(handle.n)
+  if update[0].level[0].forward != handle.n {
+    return -1, false
+  }
+  return rank, true
+}
+
+// locate walks down to target, returning the predecessor of target at every
+// level (as Insert/Remove do for a key) plus target's 0-based rank. A plain
+// key search stops as soon as it passes target's key, which is wrong when
+// keys are duplicated: it may stop at another element that merely compares
+// equal to target rather than at target itself. So locate first descends to
+// the last node with a key strictly less than target's, then walks forward
+// by pointer through the run of equal keys until it reaches target,
+// refining update[i] and rank to match as it goes.
+func (z *SkipList[K, V]) locate(target *slNode[K, V]) (update []*slNode[K, V], rank int) {
+  update = make([]*slNode[K, V], MaxLevel)
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    for x.level[i].forward != nil && z.cmp(x.level[i].forward.key, target.key) {
+      rank += x.level[i].span
+      x = x.level[i].forward
+    }
+    update[i] = x
+  }
+
+  for x.level[0].forward != target && x.level[0].forward != nil {
+    rank += x.level[0].span
+    x = x.level[0].forward
+    for i := 0; i < len(x.level); i++ {
+      update[i] = x
+    }
+  }
+  return update, rank
+}
+
+// Range returns the entries whose rank is between start and stop.
+// Both arguments, start and stop are inclusive, and are 0 based.
+func (z *SkipList[K, V]) Range(start, stop int) (reply []Pair[K, V]) {
+  if start > stop || start >= z.length {
+    return nil
+  }
+  if stop >= z.length {
+    stop = z.length - 1
+  }
+
+  ln := z.getElementByRank(start)
+  for rangelen := stop - start + 1; rangelen > 0; rangelen-- {
+    reply = append(reply, Pair[K, V]{Key: ln.key, Value: ln.value})
+    ln = ln.level[0].forward
+  }
+
+  return
+}
+
+// RangeByScore returns entries within the range spec in ascending order.
+func (z *SkipList[K, V]) RangeByScore(spec RangeSpec[K], offset, limit int) (reply []Pair[K, V]) {
+  ln := z.firstInRange(spec)
+  if ln == nil {
+    return
+  }
+
+  for ln != nil && offset > 0 {
+    offset--
+    ln = ln.level[0].forward
+  }
+
+  for ln != nil && limit > 0 {
+    limit--
+    if !spec.lteMax(z.cmp, ln.key) {
+      break
+    }
+
+    reply = append(reply, Pair[K, V]{Key: ln.key, Value: ln.value})
+
+    ln = ln.level[0].forward
+  }
+
+  return
+}
+
+// SampleInRange is similar to RangeByScore in that it also returns entries
+// within the range spec. The difference is however, the entries returned
+// by SampleInRange are randomly and evenly sampled from the range.
+func (z *SkipList[K, V]) SampleInRange(spec RangeSpec[K], limit int) (reply []Pair[K, V]) {
+  if !z.isInRange(spec) {
+    return nil
+  }
+
+  firstNodePath, firstNodeRanks := z.firstNodeInRange(spec)
+  if firstNodePath == nil || firstNodeRanks == nil {
+    return nil
+  }
+
+  lastNodeRank := z.RankOfLastInRange(spec)
+  if lastNodeRank == -1 {
+    return nil
+  }
+
+  ranks := Sample(limit, lastNodeRank-firstNodeRanks[0]+1)
+  for i := 0; i < len(ranks); i++ {
+    ranks[i] += firstNodeRanks[0]
+  }
+  sort.Ints(ranks)
+
+  level := 0
+  numElem := lastNodeRank - firstNodeRanks[0]
+  for numElem > limit && level < z.level-1 {
+    numElem /= 2
+    level++
+  }
+
+  levelNode := firstNodePath[level]
+  levelNodeRank := firstNodeRanks[level]
+  for _, rank := range ranks {
+    if levelNode.level[level].forward != nil && levelNodeRank+levelNode.level[level].span <= rank+1 {
+      levelNodeRank += levelNode.level[level].span
+      levelNode = levelNode.level[level].forward
+    }
+
+    x := levelNode
+    traversed := levelNodeRank
+    for i := level; i >= 0; i-- {
+      for x.level[i].forward != nil && (traversed+x.level[i].span) <= rank+1 {
+        traversed += x.level[i].span
+        x = x.level[i].forward
+      }
+      if traversed == rank+1 {
+        reply = append(reply, Pair[K, V]{Key: x.key, Value: x.value})
+        break
+      }
+    }
+  }
+
+  return
+}
+
+// WeightedSampleInRange is like SampleInRange but biases the sample toward
+// higher-weighted elements, using A-Res weighted reservoir sampling: for
+// each candidate it computes a key u^(1/weight) with u ~ U(0,1), and keeps
+// the limit entries with the largest keys.
+//
+// Running A-Res over every entry in spec would cost O(N). Instead, as a
+// fast path, WeightedSampleInRange first draws O(limit*log N) candidates
+// uniformly via the same level-walk SampleInRange uses, then runs A-Res
+// over just those candidates. This trades a small amount of bias (an entry
+// that is never drawn as a candidate can never be picked, however high its
+// weight) to stay close to SampleInRange's performance.
+func (z *SkipList[K, V]) WeightedSampleInRange(spec RangeSpec[K], limit int, weight func(K) float64) (reply []Pair[K, V]) {
+  if limit <= 0 {
+    return nil
+  }
+
+  candidateCount := limit * z.level
+  candidates := z.SampleInRange(spec, candidateCount)
+  return weightedReservoir(candidates, limit, weight)
+}
+
+type aresItem[K any, V any] struct {
+  key  float64
+  pair Pair[K, V]
+}
+
+type aresHeap[K any, V any] []aresItem[K, V]
+
+func (h aresHeap[K, V]) Len() int            { return len(h) }
+func (h aresHeap[K, V]) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h aresHeap[K, V]) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *aresHeap[K, V]) Push(x interface{}) { *h = append(*h, x.(aresItem[K, V])) }
+func (h *aresHeap[K, V]) Pop() interface{} {
+  old := *h
+  n := len(old)
+  item := old[n-1]
+  *h = old[:n-1]
+  return item
+}
+
+// weightedReservoir runs the A-Res algorithm over candidates, keeping the
+// limit candidates with the largest u^(1/weight) key. Entries with a
+// non-positive weight are never picked.
+func weightedReservoir[K any, V any](candidates []Pair[K, V], limit int, weight func(K) float64) []Pair[K, V] {
+  if limit >= len(candidates) {
+    out := make([]Pair[K, V], 0, len(candidates))
+    for _, c := range candidates {
+      if weight(c.Key) > 0 {
+        out = append(out, c)
+      }
+    }
+    return out
+  }
+
+  h := make(aresHeap[K, V], 0, limit)
+  for _, c := range candidates {
+    w := weight(c.Key)
+    if w <= 0 {
+      continue
+    }
+    key := math.Pow(rand.Float64(), 1/w)
+    if h.Len() < limit {
+      heap.Push(&h, aresItem[K, V]{key: key, pair: c})
+    } else if key > h[0].key {
+      heap.Pop(&h)
+      heap.Push(&h, aresItem[K, V]{key: key, pair: c})
+    }
+  }
+
+  out := make([]Pair[K, V], len(h))
+  for i, item := range h {
+    out[i] = item.pair
+  }
+  return out
+}
+
+// RankOfFirstInRange returns the rank of the first entry in the range spec.
+// A rank of -1 means that no entry exists in the range spec.
+func (z *SkipList[K, V]) RankOfFirstInRange(spec RangeSpec[K]) int {
+  _, firstNodeRanks := z.firstNodeInRange(spec)
+  if firstNodeRanks == nil {
+    return -1
+  }
+  return firstNodeRanks[0]
+}
+
+func (z *SkipList[K, V]) firstNodeInRange(spec RangeSpec[K]) ([]*slNode[K, V], []int) {
+  if !z.isInRange(spec) {
+    return nil, nil
+  }
+
+  firstNodePath := make([]*slNode[K, V], z.level)
+  firstNodeRanks := make([]int, z.level)
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    rank := 0
+    for x.level[i].forward != nil && !spec.gteMin(z.cmp, x.level[i].forward.key) {
+      rank += x.level[i].span
+      x = x.level[i].forward
+    }
+    firstNodePath[i] = x
+    firstNodeRanks[i] = rank
+  }
+
+  if !spec.lteMax(z.cmp, x.level[0].forward.key) {
+    return nil, nil
+  }
+
+  for i := z.level - 2; i >= 0; i-- {
+    firstNodeRanks[i] += firstNodeRanks[i+1]
+  }
+
+  return firstNodePath, firstNodeRanks
+}
+
+// RankOfLastInRange returns the rank of the last entry in the range spec.
+// A rank of -1 means that no entry exists in the range spec.
+func (z *SkipList[K, V]) RankOfLastInRange(spec RangeSpec[K]) int {
+  if !z.isInRange(spec) {
+    return -1
+  }
+
+  lastNodeRank := -1
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    for x.level[i].forward != nil && spec.lteMax(z.cmp, x.level[i].forward.key) {
+      lastNodeRank += x.level[i].span
+      x = x.level[i].forward
+    }
+  }
+
+  if !spec.gteMin(z.cmp, x.key) {
+    return -1
+  }
+
+  return lastNodeRank
+}
+
+func (z *SkipList[K, V]) isInRange(spec RangeSpec[K]) bool {
+  x := z.tail
+  if x == nil || !spec.gteMin(z.cmp, x.key) {
+    return false
+  }
+
+  x = z.head.level[0].forward
+  if x == nil || !spec.lteMax(z.cmp, x.key) {
+    return false
+  }
+
+  return true
+}
+
+func (z *SkipList[K, V]) firstInRange(spec RangeSpec[K]) *slNode[K, V] {
+  if !z.isInRange(spec) {
+    return nil
+  }
+
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    for x.level[i].forward != nil && !spec.gteMin(z.cmp, x.level[i].forward.key) {
+      x = x.level[i].forward
+    }
+  }
+
+  x = x.level[0].forward
+
+  if !spec.lteMax(z.cmp, x.key) {
+    return nil
+  }
+  return x
+}
+
+func (z *SkipList[K, V]) getElementByRank(rank int) *slNode[K, V] {
+  traversed := 0
+  x := z.head
+  for i := z.level - 1; i >= 0; i-- {
+    for x.level[i].forward != nil && (traversed+x.level[i].span <= rank+1) {
+      traversed += x.level[i].span
+      x = x.level[i].forward
+    }
+    if traversed == rank+1 {
+      return x
+    }
+  }
+  return nil
+}
+
+// PrintDebug prints the internal level structure of the SkipList, mirroring
+// Skiplist.PrintDebug.
+func (z *SkipList[K, V]) PrintDebug() {
+  fmt.Printf("length: %d, level: %d\n", z.length, z.level)
+
+  for i := z.level - 1; i >= 0; i-- {
+    for node := z.head; node != nil; node = node.level[i].forward {
+      if node == z.head {
+        fmt.Printf("%2v", "head")
+      } else {
+        fmt.Printf("%2v", node.key)
+      }
+      for j := 1; j < node.level[i].span; j++ {
+        fmt.Printf("__")
+      }
+    }
+    fmt.Printf("\n")
+  }
+}
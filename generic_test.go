@@ -0,0 +1,63 @@
+package skiplist
+
+import (
+  "math/rand"
+  "testing"
+)
+
+func TestWeightedSampleInRange(t *testing.T) {
+  rand.Seed(7)
+
+  N := 200
+  limit := 20
+  z := NewSkipList[int, int]()
+  for i := 0; i < N; i++ {
+    z.Insert(i, i)
+  }
+
+  // Weight linearly by key, so higher keys should be picked more often.
+  weight := func(k int) float64 { return float64(k + 1) }
+
+  loCount, hiCount := 0, 0
+  trials := 500
+  for t := 0; t < trials; t++ {
+    sampled := z.WeightedSampleInRange(RangeSpec[int]{Min: 0, Max: N - 1}, limit, weight)
+    if len(sampled) != limit {
+      continue
+    }
+    for _, p := range sampled {
+      if p.Key < N/4 {
+        loCount++
+      } else if p.Key >= 3*N/4 {
+        hiCount++
+      }
+    }
+  }
+
+  if hiCount <= loCount {
+    t.Fatalf("expected higher-weighted keys to be sampled more often, got loCount=%d hiCount=%d", loCount, hiCount)
+  }
+}
+
+func TestWeightedSampleInRangeZeroWeightExcluded(t *testing.T) {
+  rand.Seed(7)
+
+  z := NewSkipList[int, int]()
+  for i := 0; i < 50; i++ {
+    z.Insert(i, i)
+  }
+
+  weight := func(k int) float64 {
+    if k < 25 {
+      return 0
+    }
+    return 1
+  }
+
+  sampled := z.WeightedSampleInRange(RangeSpec[int]{Min: 0, Max: 49}, 10, weight)
+  for _, p := range sampled {
+    if p.Key < 25 {
+      t.Fatalf("expected only keys >= 25 to be sampled, got %d", p.Key)
+    }
+  }
+}
@@ -0,0 +1,166 @@
+package skiplist
+
+// Iterator is a cursor over a Skiplist's elements in rank order. Unlike
+// Range, which materializes a slice and re-walks from rank start on every
+// call, an Iterator steps in O(1) per call by following the backward and
+// level-0 forward pointers directly.
+//
+// A freshly returned Iterator is unpositioned: the first call to Next
+// moves it to the first element in its range, and the first call to Prev
+// moves it to the last one, so the same Iterator can drive either a
+// forward or a reverse scan.
+type Iterator struct {
+  z          *Skiplist
+  node       *slNode[Ordered, struct{}]
+  rank       int
+  lo, hi     int // inclusive rank bounds the Iterator is confined to.
+  positioned bool
+}
+
+// Iterator returns an Iterator over every element of z.
+func (z *Skiplist) Iterator() *Iterator {
+  return &Iterator{z: z, lo: 0, hi: z.Cardinality() - 1}
+}
+
+// IteratorInRange returns an Iterator over the elements of z within spec.
+func (z *Skiplist) IteratorInRange(spec RangeSpecOrdered) *Iterator {
+  lo := z.RankOfFirstInRange(spec)
+  hi := z.RankOfLastInRange(spec)
+  if lo == -1 || hi == -1 {
+    return &Iterator{z: z, lo: 0, hi: -1}
+  }
+  return &Iterator{z: z, lo: lo, hi: hi}
+}
+
+// Next moves the Iterator to the next element and reports whether it now
+// points at one.
+func (it *Iterator) Next() bool {
+  if it.lo > it.hi {
+    return false
+  }
+  if !it.positioned {
+    it.node = it.z.core.getElementByRank(it.lo)
+    it.rank = it.lo
+    it.positioned = true
+    return it.node != nil
+  }
+  if it.node == nil || it.rank >= it.hi {
+    it.node = nil
+    it.rank = it.hi + 1
+    return false
+  }
+  it.node = it.node.level[0].forward
+  it.rank++
+  return true
+}
+
+// Prev moves the Iterator to the previous element and reports whether it
+// now points at one.
+func (it *Iterator) Prev() bool {
+  if it.lo > it.hi {
+    return false
+  }
+  if !it.positioned {
+    it.node = it.z.core.getElementByRank(it.hi)
+    it.rank = it.hi
+    it.positioned = true
+    return it.node != nil
+  }
+  if it.node == nil || it.rank <= it.lo {
+    it.node = nil
+    it.rank = it.lo - 1
+    return false
+  }
+  it.node = it.node.backward
+  it.rank--
+  return true
+}
+
+// SeekByRank moves the Iterator directly to rank, reusing the same
+// span-accumulator trick getElementByRank uses, and reports whether rank
+// is within the Iterator's bounds.
+func (it *Iterator) SeekByRank(rank int) bool {
+  it.positioned = true
+  if rank < it.lo || rank > it.hi {
+    it.node = nil
+    return false
+  }
+  it.node = it.z.core.getElementByRank(rank)
+  it.rank = rank
+  return it.node != nil
+}
+
+// Seek moves the Iterator to the first element within its bounds that is
+// not less than target, and reports whether such an element exists. Like
+// getElementByRank, it walks down the underlying SkipList's levels
+// accumulating span as a running rank, so it costs O(log N) with no
+// allocation, instead of rewalking Range per probe.
+func (it *Iterator) Seek(target Ordered) bool {
+  it.positioned = true
+  if it.lo > it.hi {
+    it.node = nil
+    return false
+  }
+
+  core := it.z.core
+  x := core.head
+  rank := 0
+  for i := core.level - 1; i >= 0; i-- {
+    for x.level[i].forward != nil && core.cmp(x.level[i].forward.key, target) {
+      rank += x.level[i].span
+      x = x.level[i].forward
+    }
+  }
+  candidate := x.level[0].forward
+
+  if candidate == nil || rank > it.hi {
+    it.node = nil
+    it.rank = it.hi + 1
+    return false
+  }
+  if rank < it.lo {
+    rank = it.lo
+    candidate = core.getElementByRank(rank)
+  }
+  it.node = candidate
+  it.rank = rank
+  return true
+}
+
+// Value returns the element the Iterator currently points at, or nil if it
+// does not point at one.
+func (it *Iterator) Value() Ordered {
+  if it.node == nil {
+    return nil
+  }
+  return it.node.key
+}
+
+// Rank returns the rank of the element the Iterator currently points at.
+func (it *Iterator) Rank() int {
+  return it.rank
+}
+
+// ReverseRangeByScore returns elements within spec in descending order,
+// built on Iterator.Prev since the package otherwise has no descending
+// range scan.
+func (z *Skiplist) ReverseRangeByScore(spec RangeSpecOrdered, offset, limit int) (reply []Ordered) {
+  it := z.IteratorInRange(spec)
+  if !it.Prev() {
+    return nil
+  }
+  for offset > 0 {
+    offset--
+    if !it.Prev() {
+      return nil
+    }
+  }
+  for limit > 0 {
+    limit--
+    reply = append(reply, it.Value())
+    if !it.Prev() {
+      break
+    }
+  }
+  return
+}
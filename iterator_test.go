@@ -0,0 +1,106 @@
+package skiplist
+
+import (
+  "testing"
+)
+
+func TestIteratorForwardBackward(t *testing.T) {
+  N := 50
+  z := New()
+  for i := 0; i < N; i++ {
+    z.Insert(Int(i))
+  }
+
+  it := z.Iterator()
+  for i := 0; i < N; i++ {
+    if !it.Next() {
+      t.Fatalf("expected a next element at rank %d", i)
+    }
+    if int(it.Value().(Int)) != i || it.Rank() != i {
+      t.Fatalf("expected value %d rank %d, got value %d rank %d", i, i, int(it.Value().(Int)), it.Rank())
+    }
+  }
+  if it.Next() {
+    t.Fatalf("expected no element past the last rank")
+  }
+
+  it = z.Iterator()
+  for i := N - 1; i >= 0; i-- {
+    if !it.Prev() {
+      t.Fatalf("expected a previous element at rank %d", i)
+    }
+    if int(it.Value().(Int)) != i || it.Rank() != i {
+      t.Fatalf("expected value %d rank %d, got value %d rank %d", i, i, int(it.Value().(Int)), it.Rank())
+    }
+  }
+  if it.Prev() {
+    t.Fatalf("expected no element before the first rank")
+  }
+}
+
+func TestIteratorSeek(t *testing.T) {
+  N := 50
+  z := New()
+  for i := 0; i < N; i++ {
+    z.Insert(Int(i * 2))
+  }
+
+  it := z.Iterator()
+  if !it.Seek(Int(21)) {
+    t.Fatalf("expected Seek to find an element not less than 21")
+  }
+  if int(it.Value().(Int)) != 22 {
+    t.Fatalf("expected 22, got %d", int(it.Value().(Int)))
+  }
+
+  if !it.SeekByRank(0) {
+    t.Fatalf("expected SeekByRank(0) to succeed")
+  }
+  if int(it.Value().(Int)) != 0 {
+    t.Fatalf("expected 0, got %d", int(it.Value().(Int)))
+  }
+
+  if it.Seek(Int(1000)) {
+    t.Fatalf("expected no element not less than 1000")
+  }
+}
+
+func TestIteratorInRangeAndReverseRangeByScore(t *testing.T) {
+  N := 100
+  fixture, z := genFixtureAndSkiplist(N)
+
+  for i := 0; i < 10; i++ {
+    spec := RangeSpecOrdered{Min: Int(10), Max: Int(40)}
+
+    var forward []int
+    it := z.IteratorInRange(spec)
+    for it.Next() {
+      forward = append(forward, int(it.Value().(Int)))
+    }
+
+    var expected []int
+    for _, v := range fixture {
+      if v >= 10 && v <= 40 {
+        expected = append(expected, v)
+      }
+    }
+    if len(forward) != len(expected) {
+      t.Fatalf("expected %v, got %v", expected, forward)
+    }
+    for j := range expected {
+      if expected[j] != forward[j] {
+        t.Fatalf("expected %v, got %v", expected, forward)
+      }
+    }
+
+    reverse := z.ReverseRangeByScore(spec, 0, len(expected))
+    if len(reverse) != len(expected) {
+      t.Fatalf("expected %d elements, got %d", len(expected), len(reverse))
+    }
+    for j := range reverse {
+      if int(reverse[j].(Int)) != expected[len(expected)-1-j] {
+        t.Fatalf("expected reverse of %v, got %v", expected, reverse)
+      }
+    }
+  }
+}
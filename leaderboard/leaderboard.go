@@ -0,0 +1,159 @@
+// Package leaderboard implements the classic player-rank pattern on top of
+// skiplist.SkipList: scores are kept in rank order in the skiplist, and a
+// secondary id -> element index makes UpdateScore O(log N) instead of the
+// O(N) a plain skiplist.Skiplist.Delete would need, since it has no way to
+// find a player's old score without searching the whole list for it.
+package leaderboard
+
+import (
+  "github.com/fumin/skiplist"
+)
+
+// Hook lets a Leaderboard notify a write-behind persistence layer about
+// score changes: the skiplist stays authoritative in memory, and Flush is
+// responsible for syncing dirty ids to a database on whatever schedule the
+// caller chooses.
+type Hook[ID comparable] interface {
+  Dirty(id ID)
+  Flush() error
+}
+
+// Entry is one player's position on a Leaderboard.
+type Entry[ID comparable, Score any] struct {
+  ID    ID
+  Score Score
+  Rank  int
+}
+
+// Leaderboard ranks players of type ID by a Score, ordered by cmp. MaxCount,
+// if positive, caps the number of players kept: UpdateScore drops whichever
+// player is at the head of that ordering (the lowest score) once the cap is
+// exceeded.
+type Leaderboard[ID comparable, Score any] struct {
+  scores   *skiplist.SkipList[Score, ID]
+  index    map[ID]*skiplist.Node[Score, ID]
+  hook     Hook[ID]
+  MaxCount int
+}
+
+// New creates a Leaderboard ordered by cmp. hook may be nil if no
+// write-behind persistence is needed.
+func New[ID comparable, Score any](cmp skiplist.CompareFn[Score], hook Hook[ID]) *Leaderboard[ID, Score] {
+  return &Leaderboard[ID, Score]{
+    scores: skiplist.NewSkipListFunc[Score, ID](cmp),
+    index:  make(map[ID]*skiplist.Node[Score, ID]),
+    hook:   hook,
+  }
+}
+
+// Cardinality returns the number of players on the Leaderboard.
+func (l *Leaderboard[ID, Score]) Cardinality() int {
+  return l.scores.Cardinality()
+}
+
+// UpdateScore sets id's score, inserting id if it is not yet on the
+// Leaderboard. The id -> element index lets the old entry, if any, be
+// unlinked directly instead of searching the skiplist for it by score.
+func (l *Leaderboard[ID, Score]) UpdateScore(id ID, score Score) {
+  if old, ok := l.index[id]; ok {
+    l.scores.RemoveNode(old)
+  }
+  l.index[id] = l.scores.InsertNode(score, id)
+
+  if l.hook != nil {
+    l.hook.Dirty(id)
+  }
+
+  l.evictOverflow()
+}
+
+// Remove takes id off the Leaderboard. It returns false if id was not on it.
+func (l *Leaderboard[ID, Score]) Remove(id ID) bool {
+  node, ok := l.index[id]
+  if !ok {
+    return false
+  }
+  l.scores.RemoveNode(node)
+  delete(l.index, id)
+  return true
+}
+
+// GetRank returns id's 0-based rank and score. ok is false if id is not on
+// the Leaderboard.
+func (l *Leaderboard[ID, Score]) GetRank(id ID) (rank int, score Score, ok bool) {
+  node, found := l.index[id]
+  if !found {
+    return 0, score, false
+  }
+  rank, ok = l.scores.RankOf(node)
+  return rank, node.Key(), ok
+}
+
+// TopN returns the first n entries in ascending order.
+func (l *Leaderboard[ID, Score]) TopN(n int) []Entry[ID, Score] {
+  pairs := l.scores.Range(0, n-1)
+  entries := make([]Entry[ID, Score], len(pairs))
+  for i, p := range pairs {
+    entries[i] = Entry[ID, Score]{ID: p.Value, Score: p.Key, Rank: i}
+  }
+  return entries
+}
+
+// Around returns id itself plus up to k entries on each side of it, walking
+// the skiplist's forward/backward pointers directly instead of re-ranking
+// for every neighbor. Entries are returned in ascending rank order. ok is
+// false if id is not on the Leaderboard.
+func (l *Leaderboard[ID, Score]) Around(id ID, k int) ([]Entry[ID, Score], bool) {
+  center, ok := l.index[id]
+  if !ok {
+    return nil, false
+  }
+  centerRank, ok := l.scores.RankOf(center)
+  if !ok {
+    return nil, false
+  }
+
+  var before []Entry[ID, Score]
+  node := center
+  for i := 1; i <= k; i++ {
+    node = node.Prev()
+    if node == nil {
+      break
+    }
+    before = append(before, Entry[ID, Score]{ID: node.Value(), Score: node.Key(), Rank: centerRank - i})
+  }
+  for i, j := 0, len(before)-1; i < j; i, j = i+1, j-1 {
+    before[i], before[j] = before[j], before[i]
+  }
+
+  entries := append(before, Entry[ID, Score]{ID: id, Score: center.Key(), Rank: centerRank})
+
+  node = center
+  for i := 1; i <= k; i++ {
+    node = node.Next()
+    if node == nil {
+      break
+    }
+    entries = append(entries, Entry[ID, Score]{ID: node.Value(), Score: node.Key(), Rank: centerRank + i})
+  }
+  return entries, true
+}
+
+// Flush asks the persistence hook, if any, to sync dirty ids to storage.
+func (l *Leaderboard[ID, Score]) Flush() error {
+  if l.hook == nil {
+    return nil
+  }
+  return l.hook.Flush()
+}
+
+func (l *Leaderboard[ID, Score]) evictOverflow() {
+  if l.MaxCount <= 0 {
+    return
+  }
+  for l.scores.Cardinality() > l.MaxCount {
+    lowest := l.scores.Head()
+    l.scores.RemoveNode(lowest)
+    delete(l.index, lowest.Value())
+  }
+}
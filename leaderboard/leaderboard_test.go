@@ -0,0 +1,148 @@
+package leaderboard
+
+import (
+  "testing"
+)
+
+func less(a, b int) bool { return a < b }
+
+func TestUpdateScoreAndRank(t *testing.T) {
+  l := New[string, int](less, nil)
+  l.UpdateScore("alice", 10)
+  l.UpdateScore("bob", 20)
+  l.UpdateScore("carol", 30)
+
+  rank, score, ok := l.GetRank("bob")
+  if !ok || rank != 1 || score != 20 {
+    t.Fatalf("expected rank 1 score 20, got rank %d score %d ok %v", rank, score, ok)
+  }
+
+  // Moving bob's score should update its rank without a stale entry
+  // being left behind.
+  l.UpdateScore("bob", 40)
+  rank, score, ok = l.GetRank("bob")
+  if !ok || rank != 2 || score != 40 {
+    t.Fatalf("expected rank 2 score 40, got rank %d score %d ok %v", rank, score, ok)
+  }
+  if l.Cardinality() != 3 {
+    t.Fatalf("expected cardinality 3, got %d", l.Cardinality())
+  }
+}
+
+func TestTopN(t *testing.T) {
+  l := New[string, int](less, nil)
+  l.UpdateScore("alice", 10)
+  l.UpdateScore("bob", 20)
+  l.UpdateScore("carol", 30)
+
+  top := l.TopN(2)
+  if len(top) != 2 || top[0].ID != "alice" || top[1].ID != "bob" {
+    t.Fatalf("unexpected TopN result: %+v", top)
+  }
+}
+
+func TestAround(t *testing.T) {
+  l := New[string, int](less, nil)
+  for i, id := range []string{"a", "b", "c", "d", "e"} {
+    l.UpdateScore(id, i*10)
+  }
+
+  entries, ok := l.Around("c", 1)
+  if !ok {
+    t.Fatalf("expected c to be found")
+  }
+  want := []string{"b", "c", "d"}
+  if len(entries) != len(want) {
+    t.Fatalf("expected %v, got %+v", want, entries)
+  }
+  for i, id := range want {
+    if entries[i].ID != id {
+      t.Fatalf("expected %v, got %+v", want, entries)
+    }
+  }
+}
+
+func TestMaxCountEviction(t *testing.T) {
+  l := New[string, int](less, nil)
+  l.MaxCount = 2
+  l.UpdateScore("alice", 10)
+  l.UpdateScore("bob", 20)
+  l.UpdateScore("carol", 30)
+
+  if l.Cardinality() != 2 {
+    t.Fatalf("expected cardinality 2, got %d", l.Cardinality())
+  }
+  if _, _, ok := l.GetRank("alice"); ok {
+    t.Fatalf("expected alice to have been evicted")
+  }
+}
+
+func TestTiedScores(t *testing.T) {
+  l := New[string, int](less, nil)
+  for _, id := range []string{"alice", "bob", "carol", "dave", "eve"} {
+    l.UpdateScore(id, 10)
+  }
+
+  seen := make(map[int]string)
+  for _, id := range []string{"alice", "bob", "carol", "dave", "eve"} {
+    rank, score, ok := l.GetRank(id)
+    if !ok || score != 10 {
+      t.Fatalf("expected %s to be found with score 10, got score %d ok %v", id, score, ok)
+    }
+    if other, dup := seen[rank]; dup {
+      t.Fatalf("rank %d claimed by both %s and %s", rank, other, id)
+    }
+    seen[rank] = id
+  }
+
+  carolRank, _, ok := l.GetRank("carol")
+  if !ok {
+    t.Fatalf("expected carol to be found")
+  }
+  entries, ok := l.Around("carol", 1)
+  if !ok {
+    t.Fatalf("expected carol to be found by Around")
+  }
+  if len(entries) != 3 {
+    t.Fatalf("expected 3 entries, got %+v", entries)
+  }
+  if entries[1].ID != "carol" || entries[1].Rank != carolRank {
+    t.Fatalf("expected carol centered at rank %d, got %+v", carolRank, entries)
+  }
+
+  if !l.Remove("carol") {
+    t.Fatalf("expected carol to be removed")
+  }
+  if _, _, ok := l.GetRank("carol"); ok {
+    t.Fatalf("expected carol to be gone after Remove")
+  }
+  for _, id := range []string{"alice", "bob", "dave", "eve"} {
+    if _, _, ok := l.GetRank(id); !ok {
+      t.Fatalf("expected %s to still be found after removing carol", id)
+    }
+  }
+  if l.Cardinality() != 4 {
+    t.Fatalf("expected cardinality 4, got %d", l.Cardinality())
+  }
+}
+
+type recordingHook struct {
+  dirty []string
+}
+
+func (h *recordingHook) Dirty(id string) { h.dirty = append(h.dirty, id) }
+func (h *recordingHook) Flush() error    { return nil }
+
+func TestDirtyHook(t *testing.T) {
+  hook := &recordingHook{}
+  l := New[string, int](less, hook)
+  l.UpdateScore("alice", 10)
+  l.UpdateScore("alice", 20)
+
+  if len(hook.dirty) != 2 || hook.dirty[0] != "alice" || hook.dirty[1] != "alice" {
+    t.Fatalf("expected alice marked dirty twice, got %v", hook.dirty)
+  }
+  if err := l.Flush(); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+}
@@ -0,0 +1,252 @@
+package skiplist
+
+import (
+  "bufio"
+  "encoding"
+  "encoding/binary"
+  "fmt"
+  "io"
+  "os"
+  "sync"
+)
+
+// Snapshot streams every element of z, in rank order, to w, together with
+// the per-element level count, so that Load can rebuild forward/backward/
+// span links directly instead of re-inserting (and re-randomizing) every
+// element. Each element must implement encoding.BinaryMarshaler.
+//
+// This is motivated by the leaderboard use case: the in-memory skiplist is
+// authoritative and database writes are write-behind, so a fast restart
+// needs a native serialization format rather than replaying from the
+// database.
+func (z *Skiplist) Snapshot(w io.Writer) error {
+  bw := bufio.NewWriter(w)
+
+  if err := writeUvarint(bw, uint64(z.Cardinality())); err != nil {
+    return err
+  }
+  for node := z.core.head.level[0].forward; node != nil; node = node.level[0].forward {
+    m, ok := node.key.(encoding.BinaryMarshaler)
+    if !ok {
+      return fmt.Errorf("skiplist: element %v does not implement encoding.BinaryMarshaler", node.key)
+    }
+    data, err := m.MarshalBinary()
+    if err != nil {
+      return err
+    }
+    if err := writeUvarint(bw, uint64(len(node.level))); err != nil {
+      return err
+    }
+    if err := writeUvarint(bw, uint64(len(data))); err != nil {
+      return err
+    }
+    if _, err := bw.Write(data); err != nil {
+      return err
+    }
+  }
+
+  return bw.Flush()
+}
+
+// Load rebuilds a Skiplist from a stream written by Snapshot in O(N): it
+// wires each element's forward/backward/span directly from the recorded
+// level counts instead of calling Insert N times, which would be
+// O(N log N) and re-randomize every element's level. decode turns the
+// bytes recorded for one element back into an Ordered value.
+func Load(r io.Reader, decode func([]byte) (Ordered, error)) (*Skiplist, error) {
+  br := bufio.NewReader(r)
+
+  n, err := binary.ReadUvarint(br)
+  if err != nil {
+    return nil, err
+  }
+
+  z := New()
+  core := z.core
+
+  last := make([]*slNode[Ordered, struct{}], MaxLevel)
+  lastRank := make([]int, MaxLevel)
+  for i := range last {
+    last[i] = core.head
+    lastRank[i] = -1
+  }
+
+  var prev *slNode[Ordered, struct{}]
+  level := 1
+  for rank := 0; rank < int(n); rank++ {
+    height, err := binary.ReadUvarint(br)
+    if err != nil {
+      return nil, err
+    }
+    size, err := binary.ReadUvarint(br)
+    if err != nil {
+      return nil, err
+    }
+    data := make([]byte, size)
+    if _, err := io.ReadFull(br, data); err != nil {
+      return nil, err
+    }
+    key, err := decode(data)
+    if err != nil {
+      return nil, err
+    }
+
+    node := &slNode[Ordered, struct{}]{key: key, backward: prev, level: make([]slLevel[Ordered, struct{}], height)}
+    for i := 0; i < int(height); i++ {
+      last[i].level[i].span = rank - lastRank[i]
+      last[i].level[i].forward = node
+      last[i] = node
+      lastRank[i] = rank
+    }
+    if int(height) > level {
+      level = int(height)
+    }
+    prev = node
+  }
+  // As Insert leaves them, the last node at each level has a span equal to
+  // the number of elements after it.
+  for i := 0; i < level; i++ {
+    last[i].level[i].span = int(n) - 1 - lastRank[i]
+  }
+
+  core.tail = prev
+  core.level = level
+  core.length = int(n)
+  return z, nil
+}
+
+func writeUvarint(w *bufio.Writer, v uint64) error {
+  var buf [binary.MaxVarintLen64]byte
+  n := binary.PutUvarint(buf[:], v)
+  _, err := w.Write(buf[:n])
+  return err
+}
+
+const (
+  walOpInsert byte = iota
+  walOpDelete
+)
+
+// WAL is an append-only write-ahead log of Skiplist mutations. Replaying it
+// onto the Skiplist reconstructed by the last Load lets a crashed process
+// recover the mutations that happened after that snapshot was taken,
+// without having to wait on the database the leaderboard use case writes
+// behind to.
+type WAL struct {
+  mu sync.Mutex
+  f  *os.File
+}
+
+// OpenWAL opens (creating if necessary) the WAL file at path for appending.
+func OpenWAL(path string) (*WAL, error) {
+  f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+  if err != nil {
+    return nil, err
+  }
+  return &WAL{f: f}, nil
+}
+
+// Close closes the underlying WAL file.
+func (wal *WAL) Close() error {
+  wal.mu.Lock()
+  defer wal.mu.Unlock()
+  return wal.f.Close()
+}
+
+func (wal *WAL) append(op byte, data []byte) error {
+  wal.mu.Lock()
+  defer wal.mu.Unlock()
+
+  var header [1 + binary.MaxVarintLen64]byte
+  header[0] = op
+  n := binary.PutUvarint(header[1:], uint64(len(data)))
+  if _, err := wal.f.Write(header[:1+n]); err != nil {
+    return err
+  }
+  if _, err := wal.f.Write(data); err != nil {
+    return err
+  }
+  return wal.f.Sync()
+}
+
+// AddLogged behaves like Skiplist.Insert, but first appends the mutation to
+// wal so it can be replayed after a crash.
+func (z *Skiplist) AddLogged(wal *WAL, o Ordered) error {
+  data, err := marshalOrdered(o)
+  if err != nil {
+    return err
+  }
+  if err := wal.append(walOpInsert, data); err != nil {
+    return err
+  }
+  z.Insert(o)
+  return nil
+}
+
+// RemLogged behaves like Skiplist.Delete, but first appends the mutation to
+// wal so it can be replayed after a crash.
+func (z *Skiplist) RemLogged(wal *WAL, o Ordered) (bool, error) {
+  data, err := marshalOrdered(o)
+  if err != nil {
+    return false, err
+  }
+  if err := wal.append(walOpDelete, data); err != nil {
+    return false, err
+  }
+  return z.Delete(o), nil
+}
+
+func marshalOrdered(o Ordered) ([]byte, error) {
+  m, ok := o.(encoding.BinaryMarshaler)
+  if !ok {
+    return nil, fmt.Errorf("skiplist: element %v does not implement encoding.BinaryMarshaler", o)
+  }
+  return m.MarshalBinary()
+}
+
+// ReplayWAL applies every mutation recorded at path to z, in order. It is
+// meant to be run against the Skiplist returned by Load, to recover
+// mutations that happened after that snapshot was taken. A missing file at
+// path is treated as an empty log, not an error.
+func ReplayWAL(z *Skiplist, path string, decode func([]byte) (Ordered, error)) error {
+  f, err := os.Open(path)
+  if err != nil {
+    if os.IsNotExist(err) {
+      return nil
+    }
+    return err
+  }
+  defer f.Close()
+
+  br := bufio.NewReader(f)
+  for {
+    op, err := br.ReadByte()
+    if err == io.EOF {
+      return nil
+    }
+    if err != nil {
+      return err
+    }
+    size, err := binary.ReadUvarint(br)
+    if err != nil {
+      return err
+    }
+    data := make([]byte, size)
+    if _, err := io.ReadFull(br, data); err != nil {
+      return err
+    }
+    o, err := decode(data)
+    if err != nil {
+      return err
+    }
+
+    switch op {
+    case walOpInsert:
+      z.Insert(o)
+    case walOpDelete:
+      z.Delete(o)
+    default:
+      return fmt.Errorf("skiplist: unknown WAL op %d", op)
+    }
+  }
+}
@@ -0,0 +1,125 @@
+package skiplist
+
+import (
+  "bytes"
+  "encoding/binary"
+  "os"
+  "path/filepath"
+  "testing"
+)
+
+// MInt is an Ordered element that also implements encoding.BinaryMarshaler,
+// so it can be used with Snapshot/Load/WAL.
+type MInt int32
+
+func (i MInt) Less(o Ordered) bool {
+  return i < o.(MInt)
+}
+
+func (i MInt) MarshalBinary() ([]byte, error) {
+  buf := make([]byte, 4)
+  binary.LittleEndian.PutUint32(buf, uint32(i))
+  return buf, nil
+}
+
+func decodeMInt(data []byte) (Ordered, error) {
+  return MInt(binary.LittleEndian.Uint32(data)), nil
+}
+
+func TestSnapshotLoad(t *testing.T) {
+  z := New()
+  for i := 0; i < 500; i++ {
+    z.Insert(MInt(i))
+  }
+  for i := 0; i < 500; i += 7 {
+    z.Delete(MInt(i))
+  }
+
+  var buf bytes.Buffer
+  if err := z.Snapshot(&buf); err != nil {
+    t.Fatalf("Snapshot failed: %v", err)
+  }
+
+  loaded, err := Load(&buf, decodeMInt)
+  if err != nil {
+    t.Fatalf("Load failed: %v", err)
+  }
+
+  if loaded.Cardinality() != z.Cardinality() {
+    t.Fatalf("expected cardinality %d, got %d", z.Cardinality(), loaded.Cardinality())
+  }
+  want := z.Range(0, z.Cardinality()-1)
+  got := loaded.Range(0, loaded.Cardinality()-1)
+  if len(want) != len(got) {
+    t.Fatalf("expected %d elements, got %d", len(want), len(got))
+  }
+  for i := range want {
+    if want[i].(MInt) != got[i].(MInt) {
+      t.Fatalf("element %d: expected %v, got %v", i, want[i], got[i])
+    }
+  }
+}
+
+func TestWALReplay(t *testing.T) {
+  dir := t.TempDir()
+  walPath := filepath.Join(dir, "skiplist.wal")
+
+  z := New()
+  for i := 0; i < 100; i++ {
+    z.Insert(MInt(i))
+  }
+
+  var buf bytes.Buffer
+  if err := z.Snapshot(&buf); err != nil {
+    t.Fatalf("Snapshot failed: %v", err)
+  }
+
+  wal, err := OpenWAL(walPath)
+  if err != nil {
+    t.Fatalf("OpenWAL failed: %v", err)
+  }
+  if err := z.AddLogged(wal, MInt(1000)); err != nil {
+    t.Fatalf("AddLogged failed: %v", err)
+  }
+  if _, err := z.RemLogged(wal, MInt(5)); err != nil {
+    t.Fatalf("RemLogged failed: %v", err)
+  }
+  if err := wal.Close(); err != nil {
+    t.Fatalf("Close failed: %v", err)
+  }
+
+  // Simulate a crash: recover by loading the last snapshot and replaying
+  // the WAL onto it.
+  recovered, err := Load(&buf, decodeMInt)
+  if err != nil {
+    t.Fatalf("Load failed: %v", err)
+  }
+  if err := ReplayWAL(recovered, walPath, decodeMInt); err != nil {
+    t.Fatalf("ReplayWAL failed: %v", err)
+  }
+
+  if recovered.Cardinality() != z.Cardinality() {
+    t.Fatalf("expected cardinality %d, got %d", z.Cardinality(), recovered.Cardinality())
+  }
+  want := z.Range(0, z.Cardinality()-1)
+  got := recovered.Range(0, recovered.Cardinality()-1)
+  for i := range want {
+    if want[i].(MInt) != got[i].(MInt) {
+      t.Fatalf("element %d: expected %v, got %v", i, want[i], got[i])
+    }
+  }
+}
+
+func TestReplayWALMissingFile(t *testing.T) {
+  dir := t.TempDir()
+  walPath := filepath.Join(dir, "missing.wal")
+
+  z := New()
+  z.Insert(MInt(1))
+  if err := ReplayWAL(z, walPath, decodeMInt); err != nil {
+    t.Fatalf("expected missing WAL file to be treated as empty, got %v", err)
+  }
+  if _, err := os.Stat(walPath); err == nil {
+    t.Fatalf("ReplayWAL should not create the file")
+  }
+}
@@ -74,11 +74,11 @@ func TestRangeByScore(t *testing.T) {
   fixture, z := genFixtureAndSkiplist(N)
 
   // Randomly generate N cases
-  specs := make([]RangeSpec, N)
+  specs := make([]RangeSpecOrdered, N)
   limits := make([]int, N)
   offsets := make([]int, N)
   for i := 0; i < N; i++ {
-    spec := RangeSpec{Min: Int(rand.Intn(N))}
+    spec := RangeSpecOrdered{Min: Int(rand.Intn(N))}
     spec.Max = Int(int(spec.Min.(Int)) + rand.Intn(N-int(spec.Min.(Int))))
     spec.Minex = (rand.Intn(2) == 0)
     spec.Maxex = (rand.Intn(2) == 0)
@@ -122,10 +122,10 @@ func TestSampleInRange(t *testing.T) {
   fixture, z := genFixtureAndSkiplist(N)
 
   // Randomly generate N cases
-  specs := make([]RangeSpec, N)
+  specs := make([]RangeSpecOrdered, N)
   limits := make([]int, N)
   for i := 0; i < N; i++ {
-    spec := RangeSpec{Min: Int(rand.Intn(N))}
+    spec := RangeSpecOrdered{Min: Int(rand.Intn(N))}
     spec.Max = Int(int(spec.Min.(Int)) + rand.Intn(N-int(spec.Min.(Int))))
     spec.Minex = (rand.Intn(2) == 0)
     spec.Maxex = (rand.Intn(2) == 0)